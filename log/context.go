@@ -0,0 +1,82 @@
+package log
+
+import "log"
+
+// mergedFields returns the fields that should be attached to every message
+// this logger writes: its hierarchical name (if any), as a "logger" field,
+// followed by any key/value pairs bound via With.
+func (logger *Logger) mergedFields() []Field {
+	if logger.name == "" && len(logger.fields) == 0 {
+		return nil
+	}
+	fields := make([]Field, 0, len(logger.fields)+1)
+	if logger.name != "" {
+		fields = append(fields, Field{"logger", logger.name})
+	}
+	fields = append(fields, logger.fields...)
+	return fields
+}
+
+// prefixName prepends the logger's hierarchical name, if any, to msg for the
+// plain text output path.
+func (logger *Logger) prefixName(msg string) string {
+	if logger.name == "" {
+		return msg
+	}
+	return logger.name + ": " + msg
+}
+
+// clone returns a copy of logger that shares its writer, channels and
+// configuration but can have its name and bound fields changed
+// independently. It backs With and Named.
+func (logger *Logger) clone() *Logger {
+	logger.vmoduleMu.RLock()
+	vmoduleRules := logger.vmoduleRules
+	logger.vmoduleMu.RUnlock()
+
+	logger.subsMu.RLock()
+	levelChannels := logger.levelChannels
+	allChannels := logger.allChannels
+	logger.subsMu.RUnlock()
+
+	child := &Logger{
+		Level:           logger.Level,
+		IncludeLevel:    logger.IncludeLevel,
+		Timeout:         logger.Timeout,
+		Handler:         logger.Handler,
+		levelChannels:   levelChannels,
+		allChannels:     allChannels,
+		name:            logger.name,
+		fields:          append([]Field{}, logger.fields...),
+		vmoduleRules:    vmoduleRules,
+		levelWriter:     logger.levelWriter,
+		StackTraceLevel: logger.StackTraceLevel,
+	}
+	child.Logger = *log.New(logger.Writer(), logger.Prefix(), logger.Flags())
+	return child
+}
+
+// With returns a derived Logger that attaches the given key/value pairs to
+// every message it logs from now on, including the Fields carried by
+// Message values delivered on Split channels. Loggers returned by With can
+// be further narrowed by chaining additional With or Named calls.
+func (logger *Logger) With(keyvals ...interface{}) *Logger {
+	child := logger.clone()
+	child.fields = append(child.fields, fieldsFromKeyvals(keyvals)...)
+	return child
+}
+
+// Named returns a derived Logger whose hierarchical component name is name,
+// concatenated onto the parent logger's own name with a "." separator (e.g.
+// calling Named("handler") on a logger already Named("server.http") yields
+// "server.http.handler"). The name is attached to every message as a
+// "logger" field, following the hclog convention.
+func (logger *Logger) Named(name string) *Logger {
+	child := logger.clone()
+	if logger.name != "" {
+		child.name = logger.name + "." + name
+	} else {
+		child.name = name
+	}
+	return child
+}
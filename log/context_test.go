@@ -0,0 +1,45 @@
+package log
+
+import "testing"
+
+// TestWith checks that fields bound via With are attached to every message,
+// including ones delivered on Split channels.
+func TestWith(t *testing.T) {
+	w := &RecordWriter{}
+	l, err := NewLevel(DEBUG, false, w, "", 0)
+	if err != nil {
+		t.Error(err)
+	}
+
+	c := make(chan Message, 1)
+	l.Split(c, true)
+
+	sub := l.With("reqID", "abc123")
+	sub.Info("handled request")
+
+	msg := <-c
+	if len(msg.Fields) != 1 || msg.Fields[0].Key != "reqID" || msg.Fields[0].Value != "abc123" {
+		t.Error("expected reqID field bound via With, got:", msg.Fields)
+	}
+}
+
+// TestNamed checks that component names concatenate hierarchically and are
+// attached as a "logger" field.
+func TestNamed(t *testing.T) {
+	w := &RecordWriter{}
+	l, err := NewLevel(DEBUG, false, w, "", 0)
+	if err != nil {
+		t.Error(err)
+	}
+
+	c := make(chan Message, 1)
+	l.Split(c, true)
+
+	sub := l.Named("server").Named("http")
+	sub.Info("listening")
+
+	msg := <-c
+	if len(msg.Fields) != 1 || msg.Fields[0].Key != "logger" || msg.Fields[0].Value != "server.http" {
+		t.Error("expected hierarchical logger name, got:", msg.Fields)
+	}
+}
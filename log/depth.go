@@ -0,0 +1,41 @@
+package log
+
+import "fmt"
+
+// DebugDepth, InfoDepth, ... behave like Debug, Info, ... except depth
+// additional stack frames are skipped when Output determines the caller's
+// file/line. Wrapper libraries can use these so logged messages point at
+// their own caller instead of the wrapper itself, mirroring glog's
+// InfoDepth family.
+
+func (logger *Logger) DebugDepth(depth int, v ...interface{}) {
+	logger.prefixOutputDepth(DEBUG, fmt.Sprint(v...), depth)
+}
+
+func (logger *Logger) InfoDepth(depth int, v ...interface{}) {
+	logger.prefixOutputDepth(INFO, fmt.Sprint(v...), depth)
+}
+
+func (logger *Logger) NoticeDepth(depth int, v ...interface{}) {
+	logger.prefixOutputDepth(NOTICE, fmt.Sprint(v...), depth)
+}
+
+func (logger *Logger) WarningDepth(depth int, v ...interface{}) {
+	logger.prefixOutputDepth(WARNING, fmt.Sprint(v...), depth)
+}
+
+func (logger *Logger) ErrDepth(depth int, v ...interface{}) {
+	logger.prefixOutputDepth(ERR, fmt.Sprint(v...), depth)
+}
+
+func (logger *Logger) CritDepth(depth int, v ...interface{}) {
+	logger.prefixOutputDepth(CRIT, fmt.Sprint(v...), depth)
+}
+
+func (logger *Logger) AlertDepth(depth int, v ...interface{}) {
+	logger.prefixOutputDepth(ALERT, fmt.Sprint(v...), depth)
+}
+
+func (logger *Logger) EmergDepth(depth int, v ...interface{}) {
+	logger.prefixOutputDepth(EMERG, fmt.Sprint(v...), depth)
+}
@@ -0,0 +1,51 @@
+package log
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestInfoCallerLine checks that a plain Info call (no Depth suffix) still
+// blames its immediate caller, guarding against prefixOutput's wrapper frame
+// shifting the reported file/line.
+func TestInfoCallerLine(t *testing.T) {
+	w := &RecordWriter{}
+	l, err := NewLevel(DEBUG, false, w, "", Lshortfile)
+	if err != nil {
+		t.Error(err)
+	}
+
+	l.Info("hello")
+	if !strings.Contains(string(w.LastWrite), "depth_test.go") {
+		t.Error("expected Info to blame depth_test.go, got:", string(w.LastWrite))
+	}
+}
+
+// TestInfoDepth checks that InfoDepth(1) blames the caller of the wrapper
+// function instead of the wrapper itself, unlike a plain Info call made
+// from the same wrapper.
+func TestInfoDepth(t *testing.T) {
+	w := &RecordWriter{}
+	l, err := NewLevel(DEBUG, false, w, "", Lshortfile)
+	if err != nil {
+		t.Error(err)
+	}
+
+	wrapper := func(depth int) {
+		if depth == 0 {
+			l.Info("hello")
+		} else {
+			l.InfoDepth(depth, "hello")
+		}
+	}
+
+	wrapper(0)
+	plain := string(w.LastWrite)
+
+	wrapper(1)
+	viaDepth := string(w.LastWrite)
+
+	if plain == viaDepth {
+		t.Error("expected InfoDepth(1) to report a different call site than a plain Info call from the same wrapper")
+	}
+}
@@ -0,0 +1,104 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Handler renders a Message into the bytes that should be written to the
+// logger's underlying io.Writer. Handlers are consulted by the structured
+// logging methods (Debugw, Infow, ...) via Logger.Handler; plain messages
+// produced by Debug, Infof, etc. are unaffected and keep using Logger's
+// built-in formatting.
+//
+// When msg.Stack is non-empty (StackTraceLevel triggered), a Handler is
+// responsible for folding it into its own rendering, e.g. as a dedicated
+// field. The caller appends nothing after Handle's returned bytes, so a
+// Handler that ignores msg.Stack silently drops the captured trace.
+type Handler interface {
+	Handle(msg Message) ([]byte, error)
+}
+
+// TextHandler renders a Message the same way Logger has always formatted
+// plain messages ("LEVEL message"), with any structured fields appended as
+// space separated key=value pairs.
+type TextHandler struct{}
+
+func (TextHandler) Handle(msg Message) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(msg.Level.String())
+	b.WriteByte(' ')
+	b.WriteString(msg.Message)
+	appendFieldsText(&b, msg.Fields)
+	if msg.Stack != "" {
+		b.WriteByte('\n')
+		b.WriteString(msg.Stack)
+	}
+	return b.Bytes(), nil
+}
+
+// appendFieldsText writes fields to b as space separated key=value pairs,
+// the same way TextHandler renders them. Also used by prefixOutputFields'
+// nil-Handler fallback, so structured fields reach the primary log writer
+// even on a Logger with no Handler configured.
+func appendFieldsText(b *bytes.Buffer, fields []Field) {
+	for _, f := range fields {
+		b.WriteByte(' ')
+		b.WriteString(f.Key)
+		b.WriteByte('=')
+		b.WriteString(logfmtValue(f.Value))
+	}
+}
+
+// LogfmtHandler renders a Message in the logfmt style popularised by
+// log15/Heroku: space separated key=value pairs, e.g.
+// `level=INFO time=... msg=listening addr=:8080`.
+type LogfmtHandler struct{}
+
+func (LogfmtHandler) Handle(msg Message) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "level=%s time=%s msg=%s",
+		msg.Level.String(),
+		msg.Timestamp.Format(time.RFC3339Nano),
+		logfmtValue(msg.Message))
+	for _, f := range msg.Fields {
+		fmt.Fprintf(&b, " %s=%s", f.Key, logfmtValue(f.Value))
+	}
+	if msg.Stack != "" {
+		fmt.Fprintf(&b, " stack=%s", logfmtValue(msg.Stack))
+	}
+	return b.Bytes(), nil
+}
+
+// logfmtValue formats a single logfmt value, quoting it if it contains
+// whitespace or characters that would otherwise make the line ambiguous to
+// parse back.
+func logfmtValue(v interface{}) string {
+	s := fmt.Sprint(v)
+	if s == "" || strings.ContainsAny(s, " \t\r\n=\"") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// JSONHandler renders a Message as a single line of JSON containing its
+// level, timestamp, message, and any structured fields.
+type JSONHandler struct{}
+
+func (JSONHandler) Handle(msg Message) ([]byte, error) {
+	out := make(map[string]interface{}, len(msg.Fields)+4)
+	for _, f := range msg.Fields {
+		out[f.Key] = f.Value
+	}
+	out["level"] = msg.Level.String()
+	out["time"] = msg.Timestamp.Format(time.RFC3339Nano)
+	out["msg"] = msg.Message
+	if msg.Stack != "" {
+		out["stack"] = msg.Stack
+	}
+	return json.Marshal(out)
+}
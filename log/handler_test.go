@@ -0,0 +1,94 @@
+package log
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestStructuredFields checks that the *w methods attach Fields to the
+// Message delivered on Split channels.
+func TestStructuredFields(t *testing.T) {
+	w := &RecordWriter{}
+	l, err := NewLevel(DEBUG, false, w, "", 0)
+	if err != nil {
+		t.Error(err)
+	}
+
+	c := make(chan Message, 1)
+	l.Split(c, true)
+
+	l.Infow("listening", "addr", ":8080", "proto", "tcp")
+
+	msg := <-c
+	if len(msg.Fields) != 2 {
+		t.Fatalf("expected 2 fields, got %d", len(msg.Fields))
+	}
+	if msg.Fields[0].Key != "addr" || msg.Fields[0].Value != ":8080" {
+		t.Error("unexpected first field:", msg.Fields[0])
+	}
+	if msg.Fields[1].Key != "proto" || msg.Fields[1].Value != "tcp" {
+		t.Error("unexpected second field:", msg.Fields[1])
+	}
+}
+
+// TestStructuredFieldsNoHandler checks that the *w methods still write
+// their key/value pairs to the primary log writer when Logger.Handler is
+// nil, the default returned by New/NewLevel.
+func TestStructuredFieldsNoHandler(t *testing.T) {
+	w := &RecordWriter{}
+	l, err := NewLevel(DEBUG, false, w, "", 0)
+	if err != nil {
+		t.Error(err)
+	}
+
+	l.Infow("listening", "addr", ":8080", "proto", "tcp")
+
+	out := string(w.LastWrite)
+	if !strings.Contains(out, "addr=:8080") {
+		t.Error("expected addr=:8080 in output with no Handler set, got:", out)
+	}
+	if !strings.Contains(out, "proto=tcp") {
+		t.Error("expected proto=tcp in output with no Handler set, got:", out)
+	}
+}
+
+// TestJSONHandler checks that JSONHandler produces a parseable JSON line
+// containing the message and structured fields.
+func TestJSONHandler(t *testing.T) {
+	w := &RecordWriter{}
+	l, err := NewLevel(DEBUG, false, w, "", 0)
+	if err != nil {
+		t.Error(err)
+	}
+	l.Handler = JSONHandler{}
+
+	l.Infow("listening", "addr", ":8080")
+
+	out := string(w.LastWrite)
+	if !strings.Contains(out, `"msg":"listening"`) {
+		t.Error("expected msg field in JSON output, got:", out)
+	}
+	if !strings.Contains(out, `"addr":":8080"`) {
+		t.Error("expected addr field in JSON output, got:", out)
+	}
+}
+
+// TestLogfmtHandler checks that LogfmtHandler renders key=value pairs.
+func TestLogfmtHandler(t *testing.T) {
+	w := &RecordWriter{}
+	l, err := NewLevel(DEBUG, false, w, "", 0)
+	if err != nil {
+		t.Error(err)
+	}
+	l.Handler = LogfmtHandler{}
+
+	l.Infow("listening", "addr", ":8080")
+
+	out := string(w.LastWrite)
+	if !strings.Contains(out, "msg=listening") {
+		t.Error("expected msg=listening in logfmt output, got:", out)
+	}
+	if !strings.Contains(out, "addr=:8080") {
+		t.Error("expected addr=:8080 in logfmt output, got:", out)
+	}
+}
@@ -4,12 +4,14 @@
 package log
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
 	"log"
 	"os"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -37,13 +39,56 @@ type Logger struct {
 	// timeouts
 	MissedMessages int
 
-	// Slice containing channels that will only receive messages of Level and
-	// higher
-	levelChannels []chan Message
-
-	// Slice containing channels that will receive all messages, regardless of
-	// Level
-	allChannels []chan Message
+	// Handler, if set, is used to render structured messages (those produced
+	// by the Debugw/Infow/... family) into the final output line. If nil, the
+	// logger falls back to its plain text formatting with the fields appended
+	// as space separated key=value pairs. See
+	// JSONHandler, LogfmtHandler and TextHandler.
+	Handler Handler
+
+	// Guards levelChannels and allChannels, which Split appends to and
+	// prefixOutput/prefixOutputFields/verboseOutput range over.
+	subsMu sync.RWMutex
+
+	// Slice containing subscribers that will only receive messages of Level
+	// and higher
+	levelChannels []*subscriber
+
+	// Slice containing subscribers that will receive all messages, regardless
+	// of Level
+	allChannels []*subscriber
+
+	// Hierarchical component name set via Named, e.g. "server.http.handler".
+	// Attached to every message as a "logger" field. Empty if unset.
+	name string
+
+	// Key/value pairs bound via With, attached to every message this logger
+	// (or any logger derived from it) writes.
+	fields []Field
+
+	// Guards vmoduleRules and vmoduleCache, which are read on every V() call
+	// and rewritten by SetVmodule.
+	vmoduleMu sync.RWMutex
+
+	// Per-file/per-package level overrides installed via SetVmodule.
+	vmoduleRules []vmoduleRule
+
+	// Caches the vmoduleRule matched by a given call site (keyed by the
+	// caller's program counter; nil if no rule matched), so repeat V() calls
+	// from the same source line cost one map lookup instead of re-walking
+	// vmoduleRules.
+	vmoduleCache map[uintptr]*vmoduleRule
+
+	// Set when the writer passed to New/NewLevel implements LevelWriter,
+	// nil otherwise. lockLevelWriter uses it to route each message's level
+	// through to WriteLevel; see writer.go.
+	levelWriter *levelRoutingWriter
+
+	// StackTraceLevel, if not NULL, causes any message at that level or
+	// more severe to have a captured goroutine stack appended to both the
+	// written output and the Message delivered to Split subscribers.
+	// Defaults to NULL (off).
+	StackTraceLevel LogLevel
 
 	// Standard Go log fields
 	log.Logger
@@ -55,6 +100,14 @@ type Message struct {
 	Level     LogLevel  // The level of the message
 	Message   string    // The content of the message, represented as a string
 	Timestamp time.Time // Timestamp of when the message was received
+	Fields    []Field   // Structured key/value pairs attached via the *w methods, With or Named
+	Stack     string    // Captured goroutine stack, set when Logger.StackTraceLevel triggers it
+}
+
+// Field is a single structured key/value pair attached to a log Message.
+type Field struct {
+	Key   string
+	Value interface{}
 }
 
 // String values for each of the log levels
@@ -125,7 +178,15 @@ func (v LogLevel) Int() int {
 func New(out io.Writer, prefix string, flag int) (newLogger *Logger) {
 	// Note that the false prevents the log from printing the urgency prefix, so
 	// that it behaves exactly like stdlib logs.
-	return &Logger{DEBUG, false, 1 * time.Second, 0, nil, nil, *log.New(out, prefix, flag)}
+	out, lw := wrapLevelWriter(out)
+	return &Logger{
+		Level:           DEBUG,
+		IncludeLevel:    false,
+		Timeout:         1 * time.Second,
+		levelWriter:     lw,
+		StackTraceLevel: NULL,
+		Logger:          *log.New(out, prefix, flag),
+	}
 }
 
 // Create a new logger with the specified level.
@@ -135,10 +196,46 @@ func NewLevel(level LogLevel, inc bool, out io.Writer, prefix string, flag int)
 		return nil, InvalidLogLevelError
 	}
 
-	newLogger = &Logger{level, inc, 1 * time.Second, 0, nil, nil, *log.New(out, prefix, flag)}
+	out, lw := wrapLevelWriter(out)
+	newLogger = &Logger{
+		Level:           level,
+		IncludeLevel:    inc,
+		Timeout:         1 * time.Second,
+		levelWriter:     lw,
+		StackTraceLevel: NULL,
+		Logger:          *log.New(out, prefix, flag),
+	}
 	return
 }
 
+// wrapLevelWriter returns out unchanged if it doesn't implement LevelWriter.
+// Otherwise it wraps out in an adapter that satisfies plain io.Writer (for
+// log.Logger to write to) and returns that adapter a second time so the
+// caller can record it on Logger.levelWriter, letting lockLevelWriter route
+// each message's level through to WriteLevel.
+func wrapLevelWriter(out io.Writer) (io.Writer, *levelRoutingWriter) {
+	lw, ok := out.(LevelWriter)
+	if !ok {
+		return out, nil
+	}
+	rw := &levelRoutingWriter{w: lw}
+	return rw, rw
+}
+
+// lockLevelWriter, when logger's writer implements LevelWriter, records
+// level as the one that the next write should be attributed to and returns
+// a func that releases the lock; callers should defer it around the Output
+// call it guards so concurrent messages can't race on which level a write
+// belongs to. Returns a no-op when there is no LevelWriter.
+func (logger *Logger) lockLevelWriter(level LogLevel) func() {
+	if logger.levelWriter == nil {
+		return func() {}
+	}
+	logger.levelWriter.mu.Lock()
+	logger.levelWriter.level = level
+	return logger.levelWriter.mu.Unlock
+}
+
 // Accepts a string with the level name or an int corresponding to the level and
 // returns the correct level.
 func ParseLevel(input interface{}) (level LogLevel, err error) {
@@ -172,49 +269,194 @@ func ParseLevel(input interface{}) (level LogLevel, err error) {
 	return LogLevel(n), err
 }
 
-// Split accepts a channel that will receive log messages in addition to them
-// being sent to the logger's io.Writer. If sendAll is true then all messages,
-// regardless of the configured logging level, will be sent to the channel.
-func (logger *Logger) Split(c chan Message, sendAll bool) {
-	if sendAll {
-		logger.allChannels = append(logger.allChannels, c)
-	} else {
-		logger.levelChannels = append(logger.levelChannels, c)
+// dispatch hands msg to every registered subscriber, honoring each one's
+// overflow policy, and reports whether level is high enough to also write
+// to logger's own io.Writer, plus the stack trace (if any) captured for the
+// message per StackTraceLevel.
+func (logger *Logger) dispatch(level LogLevel, msg string, fields []Field) (enabled bool, stack string) {
+	stack = logger.captureStackIfNeeded(level)
+	m := Message{level, msg, time.Now(), fields, stack}
+
+	logger.subsMu.RLock()
+	all := logger.allChannels
+	enabled = level <= logger.Level
+	var levelSubs []*subscriber
+	if enabled {
+		levelSubs = logger.levelChannels
+	}
+	logger.subsMu.RUnlock()
+
+	for _, sub := range all {
+		logger.enqueue(sub, m)
+	}
+	for _, sub := range levelSubs {
+		logger.enqueue(sub, m)
+	}
+	return enabled, stack
+}
+
+// withStack appends stack to text, separated by a newline, if stack is
+// non-empty.
+func withStack(text, stack string) string {
+	if stack == "" {
+		return text
 	}
+	return text + "\n" + stack
 }
 
 // prefixOutput obeys advanced logging rules and prepends prefixes before
 // passing the final message to logger.Output().
 func (logger *Logger) prefixOutput(level LogLevel, msg string) {
-	// Send the message to channels that want all messages
-	for _, c := range logger.allChannels {
-		select {
-		case c <- Message{level, msg, time.Now()}:
-		case <-time.After(logger.Timeout):
-			logger.MissedMessages++
-		}
+	// depth 1 accounts for this function itself being an extra stack frame
+	// on top of the ones prefixOutputDepth's own callers (DebugDepth and
+	// friends) already skip, so plain Debug/Info/... still blame their
+	// immediate caller.
+	logger.prefixOutputDepth(level, msg, 1)
+}
+
+// prefixOutputDepth is prefixOutput with an additional number of stack
+// frames to skip when Output determines the caller's file/line, so wrapper
+// libraries can make Output blame their own caller instead of themselves.
+// It backs prefixOutput and the DebugDepth/InfoDepth/... family.
+func (logger *Logger) prefixOutputDepth(level LogLevel, msg string, depth int) {
+	// Return if the message level isn't high enough
+	enabled, stack := logger.dispatch(level, msg, logger.mergedFields())
+	if !enabled {
+		return
+	}
+	defer logger.lockLevelWriter(level)()
+
+	text := withStack(logger.prefixName(msg), stack)
+	if logger.IncludeLevel {
+		// If we should include the level, prepend it.
+		logger.Output(3+depth, level.String()+" "+text)
+	} else { // Otherwise, give the message without any modifications.
+		logger.Output(3+depth, text)
+	}
+}
+
+// verboseOutput writes a message unconditionally, the same way prefixOutput
+// does except it never compares level against logger.Level: the caller (V)
+// has already decided, honoring any Vmodule override, that the message
+// should be emitted. It backs the Verbose methods returned by V.
+func (logger *Logger) verboseOutput(level LogLevel, msg string) {
+	logger.subsMu.RLock()
+	all := logger.allChannels
+	levelSubs := logger.levelChannels
+	logger.subsMu.RUnlock()
+
+	stack := logger.captureStackIfNeeded(level)
+	m := Message{level, msg, time.Now(), logger.mergedFields(), stack}
+	for _, sub := range all {
+		logger.enqueue(sub, m)
+	}
+	for _, sub := range levelSubs {
+		logger.enqueue(sub, m)
+	}
+	defer logger.lockLevelWriter(level)()
+
+	text := withStack(logger.prefixName(msg), stack)
+	if logger.IncludeLevel {
+		logger.Output(3, level.String()+" "+text)
+	} else {
+		logger.Output(3, text)
 	}
+}
+
+// prefixOutputFields behaves like prefixOutput but additionally attaches the
+// supplied structured fields to the Message delivered on Split channels, and
+// renders them into the final output line via logger.Handler. It backs the
+// Debugw/Infow/... family of methods.
+func (logger *Logger) prefixOutputFields(level LogLevel, msg string, fields []Field) {
+	fields = append(logger.mergedFields(), fields...)
 
 	// Return if the message level isn't high enough
-	if level > logger.Level {
+	enabled, stack := logger.dispatch(level, msg, fields)
+	if !enabled {
 		return
 	}
-
-	// Send the message to channels that only want messages of certain levels
-	for _, c := range logger.levelChannels {
-		select {
-		case c <- Message{level, msg, time.Now()}:
-		case <-time.After(logger.Timeout):
-			logger.MissedMessages++
+	defer logger.lockLevelWriter(level)()
+
+	if logger.Handler != nil {
+		// The Handler is responsible for folding msg.Stack into its own
+		// rendering (e.g. a "stack" field), since appending it as raw text
+		// here would break a structured format like JSONHandler's.
+		if b, err := logger.Handler.Handle(Message{level, msg, time.Now(), fields, stack}); err == nil {
+			logger.Output(3, string(b))
+			return
 		}
 	}
 
+	var b bytes.Buffer
+	b.WriteString(logger.prefixName(msg))
+	appendFieldsText(&b, fields)
+	text := withStack(b.String(), stack)
 	if logger.IncludeLevel {
-		// If we should include the level, prepend it.
-		logger.Output(3, level.String()+" "+msg)
-	} else { // Otherwise, give the message without any modifications.
-		logger.Output(3, msg)
+		logger.Output(3, level.String()+" "+text)
+	} else {
+		logger.Output(3, text)
+	}
+}
+
+// fieldsFromKeyvals converts an alternating key, value, key, value... slice
+// into a Field slice, as accepted by the Debugw/Infow/... methods. A key
+// missing its value is paired with the string "MISSING".
+func fieldsFromKeyvals(keyvals []interface{}) []Field {
+	fields := make([]Field, 0, (len(keyvals)+1)/2)
+	for i := 0; i < len(keyvals); i += 2 {
+		key, ok := keyvals[i].(string)
+		if !ok {
+			key = fmt.Sprint(keyvals[i])
+		}
+		var value interface{} = "MISSING"
+		if i+1 < len(keyvals) {
+			value = keyvals[i+1]
+		}
+		fields = append(fields, Field{key, value})
 	}
+	return fields
+}
+
+func (logger *Logger) Debugw(msg string, keyvals ...interface{}) {
+	logger.prefixOutputFields(DEBUG, msg, fieldsFromKeyvals(keyvals))
+}
+
+func (logger *Logger) Infow(msg string, keyvals ...interface{}) {
+	logger.prefixOutputFields(INFO, msg, fieldsFromKeyvals(keyvals))
+}
+
+func (logger *Logger) Noticew(msg string, keyvals ...interface{}) {
+	logger.prefixOutputFields(NOTICE, msg, fieldsFromKeyvals(keyvals))
+}
+
+func (logger *Logger) Warningw(msg string, keyvals ...interface{}) {
+	logger.prefixOutputFields(WARNING, msg, fieldsFromKeyvals(keyvals))
+}
+
+func (logger *Logger) Errw(msg string, keyvals ...interface{}) {
+	logger.prefixOutputFields(ERR, msg, fieldsFromKeyvals(keyvals))
+}
+
+func (logger *Logger) Critw(msg string, keyvals ...interface{}) {
+	logger.prefixOutputFields(CRIT, msg, fieldsFromKeyvals(keyvals))
+}
+
+func (logger *Logger) Alertw(msg string, keyvals ...interface{}) {
+	logger.prefixOutputFields(ALERT, msg, fieldsFromKeyvals(keyvals))
+}
+
+func (logger *Logger) Emergw(msg string, keyvals ...interface{}) {
+	logger.prefixOutputFields(EMERG, msg, fieldsFromKeyvals(keyvals))
+}
+
+func (logger *Logger) Fatalw(msg string, keyvals ...interface{}) {
+	logger.prefixOutputFields(EMERG, msg, fieldsFromKeyvals(keyvals))
+	os.Exit(1)
+}
+
+func (logger *Logger) Panicw(msg string, keyvals ...interface{}) {
+	logger.prefixOutputFields(EMERG, msg, fieldsFromKeyvals(keyvals))
+	panic(msg)
 }
 
 func (logger *Logger) Debug(v ...interface{}) {
@@ -3,6 +3,7 @@ package log
 import (
 	"io/ioutil"
 	"strings"
+	"sync/atomic"
 	"testing"
 )
 
@@ -61,17 +62,22 @@ func TestChannels(t *testing.T) {
 		t.Error(err)
 	}
 
-	// TODO(inhies): Check to make sure a race condition isn't possible with
-	// these go routines. I think it might be but I haven't been able to prove
-	// it.
+	// currentLevel mirrors l.Level for the lChan checker goroutine below.
+	// l.Level itself is mutated by this test's main goroutine with no
+	// synchronization (by design it's a plain field, not meant to change
+	// concurrently with logging), so a second goroutine can't read it
+	// directly without racing; currentLevel is updated via atomic
+	// store/load instead.
+	var currentLevel int64
 
 	// Make sure we only receive messages of the current level
 	lChan := make(chan Message)
+	lDone := make(chan struct{})
 	go func() {
-		for {
-			msg := <-lChan
-			if msg.Level > l.Level {
-				t.Error("Logging set to:", l.Level,
+		defer close(lDone)
+		for msg := range lChan {
+			if want := LogLevel(atomic.LoadInt64(&currentLevel)); msg.Level > want {
+				t.Error("Logging set to:", want,
 					"but a message got sent on channel at level:", msg.Level)
 			}
 		}
@@ -80,27 +86,40 @@ func TestChannels(t *testing.T) {
 	// Make sure we receive all messages
 	aChan := make(chan Message)
 	var msgsRecvd int
+	aDone := make(chan struct{})
 	go func() {
-		for {
-			_ = <-aChan
+		defer close(aDone)
+		for range aChan {
 			msgsRecvd++
 		}
 	}()
 
-	// Register our channels to receive the log messages
-	l.Split(aChan, true)  // Send all messages
-	l.Split(lChan, false) // Send only messages >= l.Leve
+	// Register our channels to receive the log messages. Use the Block
+	// policy so every send waits for its corresponding receive.
+	l.Split(aChan, true, WithOverflowPolicy(Block))  // Send all messages
+	l.Split(lChan, false, WithOverflowPolicy(Block)) // Send only messages >= l.Level
 
 	var sysLevel, msgLevel LogLevel
 	var count int
 	for sysLevel = 0; sysLevel.Int() < len(LevelNames); sysLevel++ {
 		l.Level = sysLevel
+		atomic.StoreInt64(&currentLevel, int64(sysLevel))
 		for msgLevel = 0; msgLevel.Int() < len(LevelNames); msgLevel++ {
 			l.prefixOutput(msgLevel, "Log this!")
 			count++
 		}
 	}
 
+	// Every Block-policy send has been received by the time prefixOutput
+	// returns, but the receiver may not have counted it yet. Closing both
+	// channels makes the receivers' range loops exit; waiting for that exit
+	// is what guarantees msgsRecvd has its final value by the time we read
+	// it below.
+	close(aChan)
+	close(lChan)
+	<-aDone
+	<-lDone
+
 	// Make sure that all messages were sent to aChan
 	if count != msgsRecvd {
 		t.Error("We sent", count, "messages but received", msgsRecvd)
@@ -0,0 +1,83 @@
+package log
+
+import "sync"
+
+// messageRing is a fixed-capacity ring buffer of Messages used to decouple
+// a logger's callers from a Split subscriber that can't keep up.
+type messageRing struct {
+	mu   sync.Mutex
+	buf  []Message
+	head int // index of the oldest element
+	size int // number of elements currently stored
+
+	// notify is signalled (non-blockingly) whenever an element is pushed, so
+	// the drainer goroutine can wake up from an empty buffer.
+	notify chan struct{}
+}
+
+func newMessageRing(capacity int) *messageRing {
+	return &messageRing{
+		buf:    make([]Message, capacity),
+		notify: make(chan struct{}, 1),
+	}
+}
+
+// pushDropOldest inserts m, evicting the oldest buffered message if the ring
+// is full. It reports whether a message was evicted.
+func (r *messageRing) pushDropOldest(m Message) (dropped bool) {
+	r.mu.Lock()
+	if r.size == len(r.buf) {
+		r.head = (r.head + 1) % len(r.buf)
+		r.size--
+		dropped = true
+	}
+	r.push(m)
+	r.mu.Unlock()
+	r.signal()
+	return dropped
+}
+
+// pushDropNewest inserts m only if the ring has room, otherwise discarding
+// it. It reports whether m itself was discarded.
+func (r *messageRing) pushDropNewest(m Message) (dropped bool) {
+	r.mu.Lock()
+	if r.size == len(r.buf) {
+		r.mu.Unlock()
+		return true
+	}
+	r.push(m)
+	r.mu.Unlock()
+	r.signal()
+	return false
+}
+
+// push inserts m at the end of the ring. Callers must hold r.mu and have
+// already ensured there's room.
+func (r *messageRing) push(m Message) {
+	idx := (r.head + r.size) % len(r.buf)
+	r.buf[idx] = m
+	r.size++
+}
+
+// pop removes and returns the oldest buffered message, if any.
+func (r *messageRing) pop() (Message, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.size == 0 {
+		return Message{}, false
+	}
+	m := r.buf[r.head]
+	r.buf[r.head] = Message{}
+	r.head = (r.head + 1) % len(r.buf)
+	r.size--
+	return m, true
+}
+
+// signal wakes up a drainer goroutine blocked waiting for new data, without
+// blocking itself if one is already pending.
+func (r *messageRing) signal() {
+	select {
+	case r.notify <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,210 @@
+package log
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotatingFileWriter is an io.Writer over a single log file that rotates it
+// before a write that would take it past MaxSize bytes and/or at the first
+// write after local midnight, shifting any existing rotated files up by one
+// (foo.log.1 becomes foo.log.2, and so on), optionally gzip-compressing them and
+// pruning the oldest beyond MaxBackups. It can be passed directly to
+// New/NewLevel, or wrapped in a MultiLevelWriter to route different levels
+// to different rotating files.
+type RotatingFileWriter struct {
+	// Filename is the path log lines are written to. Required.
+	Filename string
+
+	// MaxSize is the size, in bytes, at which the file is rotated. Zero
+	// disables size-based rotation.
+	MaxSize int64
+
+	// Daily, if true, rotates the file on the first write after local
+	// midnight, independent of MaxSize.
+	Daily bool
+
+	// MaxBackups is the number of rotated files to retain; the oldest are
+	// removed once exceeded. Zero retains every rotated file.
+	MaxBackups int
+
+	// Compress gzips rotated files (foo.log.1 becomes foo.log.1.gz) instead
+	// of leaving them as plain text.
+	Compress bool
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+	day  string // local date (2006-01-02) the current file was opened on
+}
+
+// Write implements io.Writer, rotating the underlying file first if p would
+// push it over MaxSize or if Daily rotation is due.
+func (w *RotatingFileWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.ensureOpen(); err != nil {
+		return 0, err
+	}
+	if w.dueForRotation(len(p)) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := w.file.Write(p)
+	w.size += int64(n)
+	return n, err
+}
+
+func (w *RotatingFileWriter) ensureOpen() error {
+	if w.file != nil {
+		return nil
+	}
+	f, err := os.OpenFile(w.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file = f
+	w.size = info.Size()
+	w.day = time.Now().Format("2006-01-02")
+	return nil
+}
+
+func (w *RotatingFileWriter) dueForRotation(n int) bool {
+	if w.MaxSize > 0 && w.size+int64(n) > w.MaxSize {
+		return true
+	}
+	return w.Daily && time.Now().Format("2006-01-02") != w.day
+}
+
+// rotate closes the current file, shifts existing backups up by one,
+// renames the current file to the new ".1", optionally compresses it and
+// prunes anything beyond MaxBackups, then opens a fresh file in its place.
+func (w *RotatingFileWriter) rotate() error {
+	if err := w.file.Close(); err != nil {
+		return err
+	}
+	w.file = nil
+
+	if err := w.shiftBackups(); err != nil {
+		return err
+	}
+	if err := os.Rename(w.Filename, w.backupName(1)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if w.Compress {
+		if err := compressBackup(w.backupName(1)); err != nil {
+			return err
+		}
+	}
+	if w.MaxBackups > 0 {
+		w.pruneBackups()
+	}
+	return w.ensureOpen()
+}
+
+// shiftBackups renames every existing foo.log.N (or foo.log.N.gz) to
+// foo.log.N+1, working from the highest N down so no rename overwrites a
+// file not yet moved.
+func (w *RotatingFileWriter) shiftBackups() error {
+	backups := w.listBackups()
+	sort.Sort(sort.Reverse(sort.IntSlice(backups)))
+	for _, n := range backups {
+		old := w.backupName(n)
+		if _, err := os.Stat(old + ".gz"); err == nil {
+			old += ".gz"
+		}
+		newName := w.backupName(n + 1)
+		if strings.HasSuffix(old, ".gz") {
+			newName += ".gz"
+		}
+		if err := os.Rename(old, newName); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// pruneBackups removes every rotated file numbered beyond MaxBackups.
+func (w *RotatingFileWriter) pruneBackups() {
+	for _, n := range w.listBackups() {
+		if n <= w.MaxBackups {
+			continue
+		}
+		name := w.backupName(n)
+		os.Remove(name)
+		os.Remove(name + ".gz")
+	}
+}
+
+func (w *RotatingFileWriter) backupName(n int) string {
+	return w.Filename + "." + strconv.Itoa(n)
+}
+
+// listBackups returns the rotation index of every existing foo.log.N or
+// foo.log.N.gz file alongside Filename.
+func (w *RotatingFileWriter) listBackups() []int {
+	entries, err := os.ReadDir(filepath.Dir(w.Filename))
+	if err != nil {
+		return nil
+	}
+
+	prefix := filepath.Base(w.Filename) + "."
+	var indexes []int
+	for _, e := range entries {
+		suffix := strings.TrimPrefix(e.Name(), prefix)
+		if suffix == e.Name() {
+			continue // name didn't have prefix
+		}
+		suffix = strings.TrimSuffix(suffix, ".gz")
+		n, err := strconv.Atoi(suffix)
+		if err != nil {
+			continue
+		}
+		indexes = append(indexes, n)
+	}
+	return indexes
+}
+
+// compressBackup gzips name in place, replacing it with name+".gz".
+func compressBackup(name string) error {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	f, err := os.Create(name + ".gz")
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(f)
+	if _, err := gz.Write(data); err != nil {
+		gz.Close()
+		f.Close()
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Remove(name)
+}
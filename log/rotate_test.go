@@ -0,0 +1,70 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestRotatingFileWriterMaxSize checks that writing past MaxSize rotates
+// the file to a ".1" backup and starts a fresh one.
+func TestRotatingFileWriterMaxSize(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "test.log")
+
+	w := &RotatingFileWriter{Filename: name, MaxSize: 10}
+	for i := 0; i < 3; i++ {
+		if _, err := w.Write([]byte("0123456789")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := os.Stat(name); err != nil {
+		t.Error("expected current log file to exist:", err)
+	}
+	if _, err := os.Stat(name + ".1"); err != nil {
+		t.Error("expected a .1 backup after exceeding MaxSize:", err)
+	}
+	if _, err := os.Stat(name + ".2"); err != nil {
+		t.Error("expected a .2 backup after a second rotation:", err)
+	}
+}
+
+// TestRotatingFileWriterMaxBackups checks that backups beyond MaxBackups
+// are pruned.
+func TestRotatingFileWriterMaxBackups(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "test.log")
+
+	w := &RotatingFileWriter{Filename: name, MaxSize: 1, MaxBackups: 1}
+	for i := 0; i < 4; i++ {
+		if _, err := w.Write([]byte("x")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := os.Stat(name + ".1"); err != nil {
+		t.Error("expected a .1 backup to survive pruning:", err)
+	}
+	if _, err := os.Stat(name + ".2"); !os.IsNotExist(err) {
+		t.Error("expected .2 backup to have been pruned")
+	}
+}
+
+// TestRotatingFileWriterCompress checks that a rotated file is gzipped
+// instead of left as plain text.
+func TestRotatingFileWriterCompress(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "test.log")
+
+	w := &RotatingFileWriter{Filename: name, MaxSize: 1, Compress: true}
+	w.Write([]byte("x"))
+	w.Write([]byte("y"))
+
+	if _, err := os.Stat(name + ".1.gz"); err != nil {
+		t.Error("expected rotated file to be gzip compressed:", err)
+	}
+	if _, err := os.Stat(name + ".1"); !os.IsNotExist(err) {
+		t.Error("expected uncompressed .1 backup to be removed after compression")
+	}
+}
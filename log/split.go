@@ -0,0 +1,178 @@
+package log
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy controls what a Split subscriber does when it cannot keep
+// up with the rate of incoming messages.
+type OverflowPolicy int
+
+const (
+	// DropOldest evicts the oldest buffered message to make room for the
+	// newest one. This is the default.
+	DropOldest OverflowPolicy = iota
+
+	// DropNewest discards the incoming message, keeping whatever is already
+	// buffered.
+	DropNewest
+
+	// Block makes the logging call wait (up to Logger.Timeout) for the
+	// subscriber to have room, exactly as Split behaved before ring buffers
+	// were introduced.
+	Block
+)
+
+// defaultBufferSize is the ring buffer capacity used when no WithBufferSize
+// option is given to Split.
+const defaultBufferSize = 64
+
+// SplitOption configures a Split registration. See WithOverflowPolicy and
+// WithBufferSize.
+type SplitOption func(*splitConfig)
+
+type splitConfig struct {
+	policy     OverflowPolicy
+	bufferSize int
+}
+
+// WithOverflowPolicy sets the policy used when a subscriber falls behind.
+func WithOverflowPolicy(p OverflowPolicy) SplitOption {
+	return func(cfg *splitConfig) { cfg.policy = p }
+}
+
+// WithBufferSize sets the subscriber's ring buffer capacity. Ignored when
+// the policy is Block, which has no buffer. Panics if n <= 0.
+func WithBufferSize(n int) SplitOption {
+	if n <= 0 {
+		panic("log: buffer size must be positive")
+	}
+	return func(cfg *splitConfig) { cfg.bufferSize = n }
+}
+
+// subscriberStats holds the atomically-updated counters backing
+// SubscriberStats.
+type subscriberStats struct {
+	enqueued      uint64
+	delivered     uint64
+	droppedOldest uint64
+	droppedNewest uint64
+}
+
+// subscriber is a single Split registration: the caller's channel, its
+// overflow policy, and (for non-Block policies) the ring buffer and drainer
+// goroutine that decouple logging calls from a slow reader.
+type subscriber struct {
+	out    chan Message
+	policy OverflowPolicy
+	ring   *messageRing // nil when policy == Block
+	stats  subscriberStats
+}
+
+// SubscriberStats is a point-in-time snapshot of delivery counters for a
+// single Split registration, returned by Logger.Stats.
+type SubscriberStats struct {
+	Enqueued      uint64 // messages handed to this subscriber
+	Delivered     uint64 // messages successfully sent on its channel
+	DroppedOldest uint64 // buffered messages evicted to make room for a newer one
+	DroppedNewest uint64 // incoming messages discarded because the buffer was full
+}
+
+func (sub *subscriber) snapshot() SubscriberStats {
+	return SubscriberStats{
+		Enqueued:      atomic.LoadUint64(&sub.stats.enqueued),
+		Delivered:     atomic.LoadUint64(&sub.stats.delivered),
+		DroppedOldest: atomic.LoadUint64(&sub.stats.droppedOldest),
+		DroppedNewest: atomic.LoadUint64(&sub.stats.droppedNewest),
+	}
+}
+
+// Split accepts a channel that will receive log messages in addition to them
+// being sent to the logger's io.Writer. If sendAll is true then all
+// messages, regardless of the configured logging level, will be sent to the
+// channel.
+//
+// By default the subscriber never blocks a logging call: messages are
+// queued onto a bounded ring buffer and delivered to c by a dedicated
+// goroutine, evicting the oldest queued message if the buffer is full. Pass
+// WithOverflowPolicy and/or WithBufferSize to change that behavior.
+func (logger *Logger) Split(c chan Message, sendAll bool, opts ...SplitOption) {
+	cfg := splitConfig{policy: DropOldest, bufferSize: defaultBufferSize}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	sub := &subscriber{out: c, policy: cfg.policy}
+	if cfg.policy != Block {
+		sub.ring = newMessageRing(cfg.bufferSize)
+		go logger.drain(sub)
+	}
+
+	logger.subsMu.Lock()
+	if sendAll {
+		logger.allChannels = append(logger.allChannels, sub)
+	} else {
+		logger.levelChannels = append(logger.levelChannels, sub)
+	}
+	logger.subsMu.Unlock()
+}
+
+// Stats returns a snapshot of delivery counters for every channel registered
+// via Split, keyed by the channel itself.
+func (logger *Logger) Stats() map[chan Message]SubscriberStats {
+	logger.subsMu.RLock()
+	defer logger.subsMu.RUnlock()
+
+	stats := make(map[chan Message]SubscriberStats, len(logger.allChannels)+len(logger.levelChannels))
+	for _, sub := range logger.allChannels {
+		stats[sub.out] = sub.snapshot()
+	}
+	for _, sub := range logger.levelChannels {
+		stats[sub.out] = sub.snapshot()
+	}
+	return stats
+}
+
+// enqueue hands m to sub per its overflow policy. Only the Block policy can
+// make the calling goroutine wait, and even then only up to logger.Timeout,
+// matching Split's pre-ring-buffer behavior.
+func (logger *Logger) enqueue(sub *subscriber, m Message) {
+	atomic.AddUint64(&sub.stats.enqueued, 1)
+
+	if sub.policy == Block {
+		select {
+		case sub.out <- m:
+			atomic.AddUint64(&sub.stats.delivered, 1)
+		case <-time.After(logger.Timeout):
+			logger.MissedMessages++
+		}
+		return
+	}
+
+	switch sub.policy {
+	case DropNewest:
+		if sub.ring.pushDropNewest(m) {
+			atomic.AddUint64(&sub.stats.droppedNewest, 1)
+		}
+	default: // DropOldest
+		if sub.ring.pushDropOldest(m) {
+			atomic.AddUint64(&sub.stats.droppedOldest, 1)
+		}
+	}
+}
+
+// drain repeatedly pops messages from sub's ring buffer and sends them on
+// sub.out, blocking on the subscriber's own pace without ever blocking a
+// logging call.
+func (logger *Logger) drain(sub *subscriber) {
+	for {
+		m, ok := sub.ring.pop()
+		if !ok {
+			<-sub.ring.notify
+			continue
+		}
+		sub.out <- m
+		atomic.AddUint64(&sub.stats.delivered, 1)
+	}
+}
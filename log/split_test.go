@@ -0,0 +1,101 @@
+package log
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+)
+
+// TestSplitDropOldest checks that a slow subscriber using the default
+// policy never blocks the logging call, and that evicted messages are
+// reflected in Stats.
+func TestSplitDropOldest(t *testing.T) {
+	l := New(ioutil.Discard, "", 0)
+
+	c := make(chan Message) // never read from, so every push must queue or drop
+	l.Split(c, true, WithBufferSize(2))
+
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 10; i++ {
+			l.Info("message", i)
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("logging calls blocked on a full DropOldest subscriber")
+	}
+
+	stats := l.Stats()[c]
+	if stats.Enqueued != 10 {
+		t.Errorf("expected 10 enqueued, got %d", stats.Enqueued)
+	}
+	if stats.DroppedOldest == 0 {
+		t.Error("expected some messages to be dropped from the full ring buffer")
+	}
+}
+
+// TestSplitDropNewest checks that DropNewest discards incoming messages
+// once the buffer is full instead of evicting older ones.
+func TestSplitDropNewest(t *testing.T) {
+	l := New(ioutil.Discard, "", 0)
+
+	c := make(chan Message)
+	l.Split(c, true, WithBufferSize(1), WithOverflowPolicy(DropNewest))
+
+	for i := 0; i < 5; i++ {
+		l.Info("message", i)
+	}
+
+	stats := l.Stats()[c]
+	if stats.DroppedNewest == 0 {
+		t.Error("expected some messages to be dropped as newest")
+	}
+	if stats.DroppedOldest != 0 {
+		t.Error("DropNewest policy should never report DroppedOldest")
+	}
+}
+
+// TestSplitBlockStats checks that a Block-policy subscriber's Enqueued
+// count tracks every message handed to it, not just Delivered.
+func TestSplitBlockStats(t *testing.T) {
+	l := New(ioutil.Discard, "", 0)
+
+	c := make(chan Message, 5)
+	l.Split(c, true, WithOverflowPolicy(Block))
+
+	for i := 0; i < 5; i++ {
+		l.Info("message", i)
+	}
+
+	stats := l.Stats()[c]
+	if stats.Enqueued != 5 {
+		t.Errorf("expected 5 enqueued, got %d", stats.Enqueued)
+	}
+	if stats.Delivered != 5 {
+		t.Errorf("expected 5 delivered, got %d", stats.Delivered)
+	}
+}
+
+// TestSplitDelivers checks that messages queued on the ring buffer
+// eventually reach the subscriber's channel.
+func TestSplitDelivers(t *testing.T) {
+	l := New(ioutil.Discard, "", 0)
+
+	c := make(chan Message, 1)
+	l.Split(c, true)
+
+	l.Info("hello")
+
+	select {
+	case msg := <-c:
+		if msg.Message != "hello" {
+			t.Errorf("expected message %q, got %q", "hello", msg.Message)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("message was never delivered")
+	}
+}
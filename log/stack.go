@@ -0,0 +1,37 @@
+package log
+
+import (
+	"runtime"
+	"sync"
+)
+
+// stackBufPool holds reusable buffers for runtime.Stack, sized up as needed
+// so a busy logger with StackTraceLevel set doesn't allocate a fresh buffer
+// on every qualifying message.
+var stackBufPool = sync.Pool{
+	New: func() interface{} { return make([]byte, 4096) },
+}
+
+// captureStack returns the current goroutine's stack trace, growing a
+// pooled buffer until it's large enough to hold the whole thing.
+func captureStack() string {
+	buf := stackBufPool.Get().([]byte)
+	n := runtime.Stack(buf, false)
+	for n >= len(buf) {
+		buf = make([]byte, 2*len(buf))
+		n = runtime.Stack(buf, false)
+	}
+	s := string(buf[:n])
+	stackBufPool.Put(buf)
+	return s
+}
+
+// captureStackIfNeeded returns the captured stack for a message at level,
+// or "" if StackTraceLevel is NULL (the default) or level isn't severe
+// enough to meet it.
+func (logger *Logger) captureStackIfNeeded(level LogLevel) string {
+	if logger.StackTraceLevel == NULL || level.Int() > logger.StackTraceLevel.Int() {
+		return ""
+	}
+	return captureStack()
+}
@@ -0,0 +1,104 @@
+package log
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestStackTraceLevel checks that a stack trace is appended to both the
+// written output and the Message once the message's level meets
+// StackTraceLevel, and that it's absent below that threshold.
+func TestStackTraceLevel(t *testing.T) {
+	w := &RecordWriter{}
+	l, err := NewLevel(DEBUG, false, w, "", 0)
+	if err != nil {
+		t.Error(err)
+	}
+	l.StackTraceLevel = ERR
+
+	c := make(chan Message, 2)
+	l.Split(c, true)
+
+	l.Info("no stack expected")
+	msg := <-c
+	if msg.Stack != "" {
+		t.Error("expected no stack trace for an INFO message below StackTraceLevel")
+	}
+	if strings.Contains(string(w.LastWrite), "goroutine") {
+		t.Error("expected no stack trace in output for an INFO message below StackTraceLevel")
+	}
+
+	l.Err("stack expected")
+	msg = <-c
+	if msg.Stack == "" {
+		t.Error("expected a stack trace on the Message for an ERR message at StackTraceLevel")
+	}
+	if !strings.Contains(string(w.LastWrite), "goroutine") {
+		t.Error("expected a stack trace appended to the written output")
+	}
+}
+
+// TestStackTraceLevelJSONHandler checks that a captured stack trace is
+// folded into JSONHandler's own "stack" field rather than appended as raw
+// text after it, so the written line stays valid JSON.
+func TestStackTraceLevelJSONHandler(t *testing.T) {
+	w := &RecordWriter{}
+	l, err := NewLevel(DEBUG, false, w, "", 0)
+	if err != nil {
+		t.Error(err)
+	}
+	l.Handler = JSONHandler{}
+	l.StackTraceLevel = ERR
+
+	l.Errw("stack expected", "addr", ":8080")
+
+	var out map[string]interface{}
+	if err := json.Unmarshal(w.LastWrite, &out); err != nil {
+		t.Fatalf("expected valid JSON output, got error %v for: %s", err, w.LastWrite)
+	}
+	stack, ok := out["stack"].(string)
+	if !ok || stack == "" {
+		t.Error("expected a non-empty stack field in the JSON output, got:", out["stack"])
+	}
+}
+
+// TestStackTraceLevelLogfmtHandler checks that a captured stack trace is
+// folded into LogfmtHandler's own stack=... field rather than appended as
+// raw text after it, so the written line stays a single logfmt line.
+func TestStackTraceLevelLogfmtHandler(t *testing.T) {
+	w := &RecordWriter{}
+	l, err := NewLevel(DEBUG, false, w, "", 0)
+	if err != nil {
+		t.Error(err)
+	}
+	l.Handler = LogfmtHandler{}
+	l.StackTraceLevel = ERR
+
+	l.Errw("stack expected", "addr", ":8080")
+
+	// logger.Output (stdlib log.Logger) always appends a trailing newline;
+	// strip it before checking that the line itself has no embedded ones.
+	out := strings.TrimSuffix(string(w.LastWrite), "\n")
+	if strings.Contains(out, "\n") {
+		t.Error("expected a single logfmt line, got embedded newlines:", out)
+	}
+	if !strings.Contains(out, "stack=") {
+		t.Error("expected a stack= field in the logfmt output, got:", out)
+	}
+}
+
+// TestStackTraceLevelOff checks that the default NULL StackTraceLevel never
+// captures a stack, preserving prior behavior.
+func TestStackTraceLevelOff(t *testing.T) {
+	w := &RecordWriter{}
+	l, err := NewLevel(DEBUG, false, w, "", 0)
+	if err != nil {
+		t.Error(err)
+	}
+
+	l.Emerg("no stack by default")
+	if strings.Contains(string(w.LastWrite), "goroutine") {
+		t.Error("expected no stack trace when StackTraceLevel is NULL")
+	}
+}
@@ -0,0 +1,140 @@
+package log
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// vmoduleRule is a single parsed Vmodule pattern (e.g. "rpc/*" or
+// "transport.go") together with the level it enables.
+type vmoduleRule struct {
+	pattern string
+	level   LogLevel
+}
+
+// Verbose is returned by V and gates a single verbose logging call. It is
+// cheap to create and, when disabled, its methods are no-ops.
+type Verbose struct {
+	enabled bool
+	logger  *Logger
+}
+
+// V reports whether level is enabled for the calling source file, honoring
+// any per-file/per-package override installed via SetVmodule. When no
+// override matches, it falls back to comparing level against logger.Level,
+// same as a plain Info call would. The result should not be stored and
+// reused across call sites, since the override only applies to the file
+// that called V.
+func (logger *Logger) V(level LogLevel) Verbose {
+	pc, file, _, ok := runtime.Caller(1)
+	if !ok {
+		return Verbose{enabled: level <= logger.Level, logger: logger}
+	}
+	return Verbose{enabled: level <= logger.vmoduleLevel(pc, file), logger: logger}
+}
+
+func (v Verbose) Info(args ...interface{}) {
+	if v.enabled {
+		v.logger.verboseOutput(INFO, fmt.Sprint(args...))
+	}
+}
+
+func (v Verbose) Infof(format string, args ...interface{}) {
+	if v.enabled {
+		v.logger.verboseOutput(INFO, fmt.Sprintf(format, args...))
+	}
+}
+
+func (v Verbose) Infoln(args ...interface{}) {
+	if v.enabled {
+		v.logger.verboseOutput(INFO, fmt.Sprintln(args...))
+	}
+}
+
+// SetVmodule parses a glog-style Vmodule spec, e.g.
+// "transport=4,rpc/*=2,file.go=3", and installs it as the set of
+// per-file/per-package level overrides consulted by V. Patterns without a
+// "/" are glob-matched (see path.Match) against the caller's file basename;
+// patterns containing a "/" are matched against both the basename glob and
+// the package directory named before the "/". An empty spec clears all
+// overrides. SetVmodule is safe to call while other goroutines are logging.
+func (logger *Logger) SetVmodule(spec string) error {
+	var rules []vmoduleRule
+	if spec != "" {
+		for _, entry := range strings.Split(spec, ",") {
+			kv := strings.SplitN(entry, "=", 2)
+			if len(kv) != 2 || kv[0] == "" {
+				return fmt.Errorf("log: invalid vmodule entry %q", entry)
+			}
+			level, err := strconv.Atoi(kv[1])
+			if err != nil {
+				return fmt.Errorf("log: invalid vmodule level in %q: %v", entry, err)
+			}
+			rules = append(rules, vmoduleRule{pattern: kv[0], level: LogLevel(level)})
+		}
+	}
+
+	logger.vmoduleMu.Lock()
+	logger.vmoduleRules = rules
+	logger.vmoduleCache = nil
+	logger.vmoduleMu.Unlock()
+	return nil
+}
+
+// vmoduleLevel returns the effective V level for the call site identified by
+// pc/file, consulting (and populating) the per-PC cache so repeat calls from
+// the same line cost a single map lookup.
+func (logger *Logger) vmoduleLevel(pc uintptr, file string) LogLevel {
+	logger.vmoduleMu.RLock()
+	rule, ok := logger.vmoduleCache[pc]
+	rules := logger.vmoduleRules
+	logger.vmoduleMu.RUnlock()
+
+	if !ok {
+		for i := range rules {
+			if matchVmodule(rules[i].pattern, file) {
+				rule = &rules[i]
+				break
+			}
+		}
+
+		logger.vmoduleMu.Lock()
+		if logger.vmoduleCache == nil {
+			logger.vmoduleCache = make(map[uintptr]*vmoduleRule)
+		}
+		logger.vmoduleCache[pc] = rule
+		logger.vmoduleMu.Unlock()
+	}
+
+	if rule == nil {
+		return logger.Level
+	}
+	return rule.level
+}
+
+// matchVmodule reports whether pattern matches file, a source path as
+// returned by runtime.Caller. Patterns without a directory component are
+// matched against the file's basename; patterns with one also require the
+// file to live under a directory matching the part before the last "/".
+func matchVmodule(pattern, file string) bool {
+	file = filepath.ToSlash(file)
+	base := path.Base(file)
+
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, base)
+		return ok
+	}
+
+	dir, glob := path.Split(pattern)
+	dir = strings.TrimSuffix(dir, "/")
+
+	ok, _ := path.Match(glob, base)
+	if !ok {
+		return false
+	}
+	return strings.Contains(file, "/"+dir+"/") || strings.HasPrefix(file, dir+"/")
+}
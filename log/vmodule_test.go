@@ -0,0 +1,58 @@
+package log
+
+import "testing"
+
+// TestV checks that V(level) is gated by the logger's Level when no Vmodule
+// override is configured.
+func TestV(t *testing.T) {
+	w := &FakeWriter{}
+	l, err := NewLevel(WARNING, true, w, "", 0)
+	if err != nil {
+		t.Error(err)
+	}
+
+	l.V(DEBUG).Info("too verbose")
+	if w.Check() {
+		t.Error("V(DEBUG) logged with Level set to WARNING")
+	}
+
+	l.V(WARNING).Info("at threshold")
+	if !w.Check() {
+		t.Error("V(WARNING) did not log with Level set to WARNING")
+	}
+}
+
+// TestSetVmodule checks that a per-file override takes effect for calls
+// from this file, and that an invalid spec returns an error.
+func TestSetVmodule(t *testing.T) {
+	w := &FakeWriter{}
+	l, err := NewLevel(WARNING, true, w, "", 0)
+	if err != nil {
+		t.Error(err)
+	}
+
+	if err := l.SetVmodule("vmodule_test.go=7"); err != nil {
+		t.Fatal(err)
+	}
+
+	l.V(DEBUG).Info("now allowed by file override")
+	if !w.Check() {
+		t.Error("expected vmodule_test.go=7 override to enable V(DEBUG)")
+	}
+
+	if err := l.SetVmodule("bad-entry"); err == nil {
+		t.Error("expected error for malformed vmodule spec")
+	}
+}
+
+func TestMatchVmodule(t *testing.T) {
+	if !matchVmodule("vmodule.go", "/src/log/vmodule.go") {
+		t.Error("expected basename pattern to match")
+	}
+	if !matchVmodule("log/*", "/src/log/vmodule.go") {
+		t.Error("expected package glob pattern to match")
+	}
+	if matchVmodule("rpc/*", "/src/log/vmodule.go") {
+		t.Error("expected pattern for a different package not to match")
+	}
+}
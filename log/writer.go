@@ -0,0 +1,60 @@
+package log
+
+import (
+	"io"
+	"sync"
+)
+
+// LevelWriter is implemented by writers that want to know the LogLevel of
+// each message they receive, such as MultiLevelWriter. Logger prefers
+// WriteLevel over plain Write for any writer passed to New/NewLevel that
+// implements this interface.
+type LevelWriter interface {
+	WriteLevel(level LogLevel, p []byte) (n int, err error)
+}
+
+// levelRoutingWriter adapts a LevelWriter to the plain io.Writer that the
+// embedded log.Logger writes to. Logger cannot reach into log.Logger's own
+// internal mutex, so lockLevelWriter locks mu and records the level of the
+// message about to be written; Write then forwards it to WriteLevel. Callers
+// must hold mu for the full duration of the Output call that triggers Write.
+type levelRoutingWriter struct {
+	mu    sync.Mutex
+	level LogLevel
+	w     LevelWriter
+}
+
+func (lw *levelRoutingWriter) Write(p []byte) (int, error) {
+	return lw.w.WriteLevel(lw.level, p)
+}
+
+// MultiLevelWriter routes a message to Default, if set, and additionally to
+// whichever writer in Writers is registered for the message's LogLevel, e.g.
+// routing everything to an "all.log" Default while also sending ERR
+// messages to a separate "err.log". A Writers entry receives only messages
+// of exactly its level, not those of more severe levels. It implements both
+// io.Writer, treating plain writes as INFO, and LevelWriter.
+type MultiLevelWriter struct {
+	// Writers maps a LogLevel to an additional writer that should receive
+	// messages at that level.
+	Writers map[LogLevel]io.Writer
+
+	// Default, if set, receives every message regardless of level.
+	Default io.Writer
+}
+
+func (m *MultiLevelWriter) Write(p []byte) (int, error) {
+	return m.WriteLevel(INFO, p)
+}
+
+func (m *MultiLevelWriter) WriteLevel(level LogLevel, p []byte) (n int, err error) {
+	if m.Default != nil {
+		n, err = m.Default.Write(p)
+	}
+	if w, ok := m.Writers[level]; ok {
+		if n2, err2 := w.Write(p); err == nil {
+			n, err = n2, err2
+		}
+	}
+	return n, err
+}
@@ -0,0 +1,32 @@
+package log
+
+import (
+	"io"
+	"testing"
+)
+
+// TestMultiLevelWriter checks that Default receives every message while a
+// level-specific writer only receives messages at that level.
+func TestMultiLevelWriter(t *testing.T) {
+	all := &RecordWriter{}
+	errs := &RecordWriter{}
+	m := &MultiLevelWriter{Default: all, Writers: map[LogLevel]io.Writer{ERR: errs}}
+
+	l, err := NewLevel(DEBUG, false, m, "", 0)
+	if err != nil {
+		t.Error(err)
+	}
+
+	l.Info("just info")
+	if string(all.LastWrite) == "" {
+		t.Error("expected Default to receive the INFO message")
+	}
+	if string(errs.LastWrite) != "" {
+		t.Error("expected the ERR writer not to receive an INFO message")
+	}
+
+	l.Err("something broke")
+	if errs.LastWrite == nil {
+		t.Error("expected the ERR writer to receive the ERR message")
+	}
+}